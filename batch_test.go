@@ -0,0 +1,66 @@
+package ed25519
+
+import (
+	"crypto/rand"
+	"testing"
+)
+
+func makeBatchEntry(t *testing.T, message []byte) BatchEntry {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BatchEntry{
+		PublicKey: pub,
+		Message:   message,
+		Signature: Sign(priv, message),
+	}
+}
+
+func TestVerifyBatchAllValid(t *testing.T) {
+	entries := make([]BatchEntry, 8)
+	for i := range entries {
+		entries[i] = makeBatchEntry(t, []byte("batch message"))
+	}
+
+	ok, badIndex := VerifyBatch(entries)
+	if !ok {
+		t.Fatalf("VerifyBatch rejected a batch of valid signatures, badIndex=%d", badIndex)
+	}
+}
+
+func TestVerifyBatchDetectsBadSignature(t *testing.T) {
+	entries := make([]BatchEntry, 8)
+	for i := range entries {
+		entries[i] = makeBatchEntry(t, []byte("batch message"))
+	}
+	entries[3].Signature[0] ^= 1
+
+	ok, badIndex := VerifyBatch(entries)
+	if ok {
+		t.Fatal("VerifyBatch accepted a batch containing a corrupted signature")
+	}
+	if badIndex != 3 {
+		t.Fatalf("badIndex = %d, want 3", badIndex)
+	}
+}
+
+func TestVerifyBatchDetectsBadPublicKey(t *testing.T) {
+	entries := make([]BatchEntry, 4)
+	for i := range entries {
+		entries[i] = makeBatchEntry(t, []byte("batch message"))
+	}
+	otherPub, _, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries[1].PublicKey = otherPub
+
+	ok, badIndex := VerifyBatch(entries)
+	if ok {
+		t.Fatal("VerifyBatch accepted a batch with a mismatched public key")
+	}
+	if badIndex != 1 {
+		t.Fatalf("badIndex = %d, want 1", badIndex)
+	}
+}
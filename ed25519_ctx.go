@@ -0,0 +1,154 @@
+package ed25519
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+// dom2Prefix is the literal domain-separation prefix from RFC 8032,
+// section 2, used by both Ed25519ph and Ed25519ctx to keep their
+// signatures from colliding with plain Ed25519's or with each other.
+const dom2Prefix = "SigEd25519 no Ed25519 collisions"
+
+// maxContextSize is the largest context string RFC 8032 allows, since
+// its length is encoded as a single octet.
+const maxContextSize = 255
+
+// SignPh signs messageHash, which must be the SHA-512 hash of the
+// actual message, using the Ed25519ph prehash variant of Ed25519 from
+// RFC 8032. context may be empty, and must not be longer than 255
+// bytes.
+func SignPh(privateKey *[PrivateKeySize]byte, messageHash *[64]byte, context string) (*[SignatureSize]byte, error) {
+	return signWithDom2(privateKey, messageHash[:], 1, context)
+}
+
+// VerifyPh returns true iff sig is a valid Ed25519ph signature of
+// messageHash by publicKey, under the given context. It returns an
+// error if context is too long to have produced a valid signature.
+func VerifyPh(publicKey *[PublicKeySize]byte, messageHash *[64]byte, context string, sig *[SignatureSize]byte) (bool, error) {
+	return verifyWithDom2(publicKey, messageHash[:], sig, 1, context)
+}
+
+// SignCtx signs message using the Ed25519ctx variant of Ed25519 from
+// RFC 8032, which differs from plain Ed25519 only in requiring a
+// context string. context must not be empty or longer than 255 bytes.
+func SignCtx(privateKey *[PrivateKeySize]byte, message []byte, context string) (*[SignatureSize]byte, error) {
+	if len(context) == 0 {
+		return nil, errors.New("ed25519: Ed25519ctx requires a non-empty context")
+	}
+	return signWithDom2(privateKey, message, 0, context)
+}
+
+// VerifyCtx returns true iff sig is a valid Ed25519ctx signature of
+// message by publicKey, under the given context.
+func VerifyCtx(publicKey *[PublicKeySize]byte, message []byte, context string, sig *[SignatureSize]byte) (bool, error) {
+	if len(context) == 0 {
+		return false, errors.New("ed25519: Ed25519ctx requires a non-empty context")
+	}
+	return verifyWithDom2(publicKey, message, sig, 0, context)
+}
+
+// signWithDom2 is Sign, but with the SHA-512 inputs for the nonce and
+// the challenge prefixed by the RFC 8032 dom2 string for the given
+// phflag and context. The hash that expands the private key is not
+// prefixed.
+func signWithDom2(privateKey *[PrivateKeySize]byte, message []byte, phflag byte, context string) (*[SignatureSize]byte, error) {
+	if len(context) > maxContextSize {
+		return nil, errors.New("ed25519: context too long")
+	}
+	dom2 := dom2(phflag, context)
+
+	h := sha512.New()
+	h.Write(privateKey[:32])
+	var digestBytes1 [64]byte
+	expandedSecretKey := h.Sum(digestBytes1[:0])
+
+	expandedSecretKey[0] &= 248
+	expandedSecretKey[31] &= 127
+	expandedSecretKey[31] |= 64
+	var a [32]byte
+	copy(a[:], expandedSecretKey[:32])
+
+	h.Reset()
+	h.Write(dom2)
+	h.Write(expandedSecretKey[32:])
+	h.Write(message)
+	var messageDigest [64]byte
+	copy(messageDigest[:], h.Sum(nil))
+
+	var rBytes [32]byte
+	edwards25519.ScReduce(&rBytes, &messageDigest)
+
+	var R edwards25519.ExtendedGroupElement
+	edwards25519.GeScalarMultBase(&R, &rBytes)
+	var encodedR [32]byte
+	R.ToBytes(&encodedR)
+
+	h.Reset()
+	h.Write(dom2)
+	h.Write(encodedR[:])
+	h.Write(privateKey[32:])
+	h.Write(message)
+	var hramDigest [64]byte
+	copy(hramDigest[:], h.Sum(nil))
+
+	var hram, sBytes [32]byte
+	edwards25519.ScReduce(&hram, &hramDigest)
+	edwards25519.ScMulAdd(&sBytes, &hram, &a, &rBytes)
+
+	signature := new([64]byte)
+	copy(signature[:32], encodedR[:])
+	copy(signature[32:], sBytes[:])
+	return signature, nil
+}
+
+// verifyWithDom2 is Verify, but with every SHA-512 input prefixed by the
+// RFC 8032 dom2 string for the given phflag and context.
+func verifyWithDom2(publicKey *[PublicKeySize]byte, message []byte, sig *[SignatureSize]byte, phflag byte, context string) (bool, error) {
+	if len(context) > maxContextSize {
+		return false, errors.New("ed25519: context too long")
+	}
+	dom2 := dom2(phflag, context)
+
+	var A edwards25519.ExtendedGroupElement
+	if !A.FromBytes(publicKey) {
+		return false, nil
+	}
+
+	h := sha512.New()
+	h.Write(dom2)
+	h.Write(sig[:32])
+	h.Write(publicKey[:])
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	negH := scalarFrom64Bytes(digest)
+	negH.Neg(negH)
+	negH.Mod(negH, order)
+	var negHBytes [32]byte
+	encodeScalar(negHBytes[:], negH)
+
+	var sBytes [32]byte
+	copy(sBytes[:], sig[32:])
+
+	var checkR edwards25519.ProjectiveGroupElement
+	edwards25519.GeDoubleScalarMultVartime(&checkR, &negHBytes, &A, &sBytes)
+
+	var checkEncoded [32]byte
+	checkR.ToBytes(&checkEncoded)
+
+	return bytes.Equal(sig[:32], checkEncoded[:]), nil
+}
+
+// dom2 builds the RFC 8032 dom2 prefix:
+// "SigEd25519 no Ed25519 collisions" || octet(phflag) || octet(len(context)) || context.
+func dom2(phflag byte, context string) []byte {
+	out := make([]byte, 0, len(dom2Prefix)+2+len(context))
+	out = append(out, dom2Prefix...)
+	out = append(out, phflag, byte(len(context)))
+	out = append(out, context...)
+	return out
+}
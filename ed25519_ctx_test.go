@@ -0,0 +1,106 @@
+package ed25519
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"strings"
+	"testing"
+)
+
+func TestSignVerifyPh(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha512.Sum512([]byte("a message to be prehashed"))
+	sig, err := SignPh(priv, &digest, "")
+	if err != nil {
+		t.Fatalf("SignPh: %v", err)
+	}
+
+	ok, err := VerifyPh(pub, &digest, "", sig)
+	if err != nil {
+		t.Fatalf("VerifyPh: %v", err)
+	}
+	if !ok {
+		t.Fatal("VerifyPh rejected a valid Ed25519ph signature")
+	}
+
+	otherDigest := sha512.Sum512([]byte("a different message"))
+	if ok, _ := VerifyPh(pub, &otherDigest, "", sig); ok {
+		t.Fatal("VerifyPh accepted a signature for the wrong message hash")
+	}
+
+	if ok, _ := VerifyPh(pub, &digest, "wrong context", sig); ok {
+		t.Fatal("VerifyPh accepted a signature under the wrong context")
+	}
+}
+
+func TestSignVerifyCtx(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("a message signed with a context")
+	sig, err := SignCtx(priv, message, "example context")
+	if err != nil {
+		t.Fatalf("SignCtx: %v", err)
+	}
+
+	ok, err := VerifyCtx(pub, message, "example context", sig)
+	if err != nil {
+		t.Fatalf("VerifyCtx: %v", err)
+	}
+	if !ok {
+		t.Fatal("VerifyCtx rejected a valid Ed25519ctx signature")
+	}
+
+	if ok, _ := VerifyCtx(pub, message, "different context", sig); ok {
+		t.Fatal("VerifyCtx accepted a signature under the wrong context")
+	}
+
+	if _, err := SignCtx(priv, message, ""); err == nil {
+		t.Fatal("SignCtx accepted an empty context")
+	}
+}
+
+func TestSignCtxRejectsOversizedContext(t *testing.T) {
+	_, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context := strings.Repeat("x", 256)
+	if _, err := SignCtx(priv, []byte("message"), context); err == nil {
+		t.Fatal("SignCtx accepted a context longer than 255 bytes")
+	}
+}
+
+func TestEd25519phAndEd25519ctxDoNotCollideWithEd25519(t *testing.T) {
+	pub, priv, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte("shared message")
+	plainSig := Sign(priv, message)
+
+	ctxSig, err := SignCtx(priv, message, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *plainSig == *ctxSig {
+		t.Fatal("Ed25519ctx produced the same signature as plain Ed25519")
+	}
+
+	digest := sha512.Sum512(message)
+	phSig, err := SignPh(priv, &digest, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *plainSig == *phSig {
+		t.Fatal("Ed25519ph produced the same signature as plain Ed25519")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2013 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import "math/bits"
+
+// This file implements the scalar arithmetic Sign needs on the secret
+// nonce and secret key scalar: reducing a SHA-512 digest mod the group
+// order, and computing a*b+c mod the group order. Unlike the math/big
+// arithmetic it replaces, every operation here runs in time independent
+// of its inputs, so it is safe to use on secret scalars.
+
+// scalarOrder is the order of the edwards25519 base point, 2^252 +
+// 27742317777372353535851937790883648493, as four 64-bit
+// little-endian limbs.
+var scalarOrder = [4]uint64{
+	0x5812631a5cf5d3ed,
+	0x14def9dea2f79cd6,
+	0x0000000000000000,
+	0x1000000000000000,
+}
+
+// scSub sets diff = a-b and returns the limbs together with the borrow
+// out of the top limb (1 if a < b).
+func scSub(a, b [4]uint64) (diff [4]uint64, borrow uint64) {
+	diff[0], borrow = bits.Sub64(a[0], b[0], 0)
+	diff[1], borrow = bits.Sub64(a[1], b[1], borrow)
+	diff[2], borrow = bits.Sub64(a[2], b[2], borrow)
+	diff[3], borrow = bits.Sub64(a[3], b[3], borrow)
+	return diff, borrow
+}
+
+// scCondSubtractOrder subtracts the group order from r if r >= order,
+// and leaves r unchanged otherwise. It is constant time: both the
+// subtraction and the selection between its result and r are computed
+// unconditionally, so no branch or memory access depends on r.
+func scCondSubtractOrder(r [4]uint64) [4]uint64 {
+	diff, borrow := scSub(r, scalarOrder)
+	mask := borrow - 1 // all-ones if borrow == 0 (r >= order), all-zero otherwise
+	var out [4]uint64
+	for i := range out {
+		out[i] = (r[i] &^ mask) | (diff[i] & mask)
+	}
+	return out
+}
+
+// scReduceWide reduces the little-endian 512-bit integer held in limbs
+// modulo the group order, processing the bits from the most
+// significant limb down. This is binary long division: the invariant
+// 0 <= r < order holds after every bit, so a single conditional
+// subtraction per bit suffices.
+func scReduceWide(limbs [8]uint64) [4]uint64 {
+	var r [4]uint64
+	for i := 7; i >= 0; i-- {
+		for bit := 63; bit >= 0; bit-- {
+			b := (limbs[i] >> uint(bit)) & 1
+
+			carry := b
+			for j := 0; j < 4; j++ {
+				nv := (r[j] << 1) | carry
+				carry = r[j] >> 63
+				r[j] = nv
+			}
+
+			r = scCondSubtractOrder(r)
+		}
+	}
+	return r
+}
+
+// scMul256 computes the full 512-bit product of a and b.
+func scMul256(a, b [4]uint64) [8]uint64 {
+	var out [8]uint64
+	for i := 0; i < 4; i++ {
+		var carry uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var c1, c2 uint64
+			out[i+j], c1 = bits.Add64(out[i+j], lo, 0)
+			out[i+j], c2 = bits.Add64(out[i+j], carry, 0)
+			carry = hi + c1 + c2
+		}
+		out[i+4] += carry
+	}
+	return out
+}
+
+// scAdd256Into adds b into the low 256 bits of wide, propagating any
+// carry into the higher limbs. ScMulAdd only ever calls this with
+// wide = a*b for a, b, c < 2^256, and a*b <= 2^512-2^257+1 while
+// c <= 2^256-1, so the sum is always less than 2^512 and the carry
+// never runs past the top limb.
+func scAdd256Into(wide [8]uint64, b [4]uint64) [8]uint64 {
+	var carry uint64
+	for i := 0; i < 4; i++ {
+		wide[i], carry = bits.Add64(wide[i], b[i], carry)
+	}
+	for i := 4; i < 8; i++ {
+		wide[i], carry = bits.Add64(wide[i], 0, carry)
+	}
+	return wide
+}
+
+func scalarToLimbs(in *[32]byte) [4]uint64 {
+	var out [4]uint64
+	for i := range out {
+		out[i] = leUint64(in[i*8 : i*8+8])
+	}
+	return out
+}
+
+func limbsToScalar(out *[32]byte, limbs [4]uint64) {
+	for i, l := range limbs {
+		putLeUint64(out[i*8:i*8+8], l)
+	}
+}
+
+func leUint64(b []byte) uint64 {
+	return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16 | uint64(b[3])<<24 |
+		uint64(b[4])<<32 | uint64(b[5])<<40 | uint64(b[6])<<48 | uint64(b[7])<<56
+}
+
+func putLeUint64(b []byte, v uint64) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+	b[4] = byte(v >> 32)
+	b[5] = byte(v >> 40)
+	b[6] = byte(v >> 48)
+	b[7] = byte(v >> 56)
+}
+
+// ScReduce sets out to the 32-byte little-endian encoding of the
+// 512-bit little-endian integer in, reduced modulo the group order.
+func ScReduce(out *[32]byte, in *[64]byte) {
+	var limbs [8]uint64
+	for i := range limbs {
+		limbs[i] = leUint64(in[i*8 : i*8+8])
+	}
+	limbsToScalar(out, scReduceWide(limbs))
+}
+
+// ScMulAdd sets out = a*b + c mod the group order, where a, b and c
+// are 32-byte little-endian integers. None of them need already be
+// reduced mod the order: Sign passes the clamped Ed25519 secret scalar
+// straight through as b, and ScReduce's (reduced) outputs as a and c.
+func ScMulAdd(out, a, b, c *[32]byte) {
+	wide := scMul256(scalarToLimbs(a), scalarToLimbs(b))
+	wide = scAdd256Into(wide, scalarToLimbs(c))
+	limbsToScalar(out, scReduceWide(wide))
+}
@@ -0,0 +1,53 @@
+package edwards25519
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// scalarOrderBig is the group order, used only by this test file to
+// build a math/big oracle for ScReduce and ScMulAdd.
+var scalarOrderBig, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+func leBytesToBig(b []byte) *big.Int {
+	rev := make([]byte, len(b))
+	for i := range b {
+		rev[i] = b[len(b)-1-i]
+	}
+	return new(big.Int).SetBytes(rev)
+}
+
+func TestScReduceMatchesBigIntMod(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		var in [64]byte
+		rand.Read(in[:])
+
+		var out [32]byte
+		ScReduce(&out, &in)
+
+		want := new(big.Int).Mod(leBytesToBig(in[:]), scalarOrderBig)
+		if got := leBytesToBig(out[:]); got.Cmp(want) != 0 {
+			t.Fatalf("ScReduce(%x) = %x, want %x", in, got, want)
+		}
+	}
+}
+
+func TestScMulAddMatchesBigIntMulAddMod(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		var a, b, c [32]byte
+		rand.Read(a[:])
+		rand.Read(b[:])
+		rand.Read(c[:])
+
+		var out [32]byte
+		ScMulAdd(&out, &a, &b, &c)
+
+		want := new(big.Int).Mul(leBytesToBig(a[:]), leBytesToBig(b[:]))
+		want.Add(want, leBytesToBig(c[:]))
+		want.Mod(want, scalarOrderBig)
+		if got := leBytesToBig(out[:]); got.Cmp(want) != 0 {
+			t.Fatalf("ScMulAdd(%x, %x, %x) = %x, want %x", a, b, c, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package edwards25519
+
+// This file adds variable-time scalar multiplication for public-input
+// operations such as signature verification, where the strictly
+// constant-time double-and-add in ScalarMult and DoubleScalarMult does
+// more work than necessary: every bit costs a full addition and a
+// conditional move, whether or not that bit is set. ScalarMultVartime
+// and MultiScalarMultVartime instead use a signed, width-5 NAF and a
+// precomputed table of odd multiples, so that on average only one in
+// six bits costs an addition. Secret scalars must still go through the
+// constant-time ScalarMult and DoubleScalarMult.
+
+import "math/big"
+
+// nafWidth is the window width used below. w=5 means the precomputed
+// table holds the 8 odd multiples P, 3P, ..., 15P, and on average one in
+// w+1 digits of the NAF is non-zero.
+const nafWidth = 5
+
+// oddMultiplesTableSize is 2**(nafWidth-2), the number of odd multiples
+// from 1P up to (2**(nafWidth-1) - 1)*P held in the table.
+const oddMultiplesTableSize = 1 << (nafWidth - 2)
+
+// nafDigits is long enough to hold the width-5 NAF of any scalar that
+// fits in 32 bytes, with a little headroom for the occasional carry out
+// of the top bit.
+const nafDigits = 8*32 + 4
+
+// scalarToNAF converts k into its signed width-5 non-adjacent form: an
+// array of digits, each zero or odd with |digit| < 2**(nafWidth-1), such
+// that at most one digit in any run of nafWidth consecutive positions is
+// non-zero and sum(digits[i] * 2**i) == k. This is Algorithm 3.35 from
+// the Handbook of Applied Cryptography, computed with math/big since
+// this code path is variable-time regardless.
+func scalarToNAF(k *[32]byte) [nafDigits]int8 {
+	var be [32]byte
+	for i := range k {
+		be[i] = k[31-i]
+	}
+	K := new(big.Int).SetBytes(be[:])
+
+	var out [nafDigits]int8
+	width := big.NewInt(1 << nafWidth)
+	half := int64(1) << (nafWidth - 1)
+
+	for i := 0; K.Sign() > 0; i++ {
+		if K.Bit(0) == 1 {
+			m := new(big.Int).Mod(K, width).Int64()
+			if m >= half {
+				m -= 1 << nafWidth
+			}
+			out[i] = int8(m)
+			K.Sub(K, big.NewInt(m))
+		}
+		K.Rsh(K, 1)
+	}
+	return out
+}
+
+// absDigit returns the absolute value of a NAF digit produced by
+// scalarToNAF.
+func absDigit(d int8) int8 {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// buildOddMultiplesTable returns {p, 3p, 5p, ..., (2*oddMultiplesTableSize-1)*p}.
+func buildOddMultiplesTable(p *ExtendedGroupElement) [oddMultiplesTableSize]ExtendedGroupElement {
+	var table [oddMultiplesTableSize]ExtendedGroupElement
+	table[0] = *p
+
+	var twoPCompleted CompletedGroupElement
+	p.Double(&twoPCompleted)
+	var twoP ExtendedGroupElement
+	twoPCompleted.ToExtended(&twoP)
+
+	var twoPCached CachedGroupElement
+	twoP.ToCached(&twoPCached)
+
+	for i := 1; i < oddMultiplesTableSize; i++ {
+		var sum CompletedGroupElement
+		PointAdd(&sum, &table[i-1], &twoPCached)
+		sum.ToExtended(&table[i])
+	}
+
+	return table
+}
+
+// tableLookup returns a CachedGroupElement for digit*p, where table
+// holds the odd multiples of p as built by buildOddMultiplesTable and
+// digit is a non-zero NAF digit.
+func tableLookup(table *[oddMultiplesTableSize]ExtendedGroupElement, digit int8) CachedGroupElement {
+	idx := (absDigit(digit) - 1) / 2
+	term := table[idx]
+	if digit < 0 {
+		FeNeg(&term.X, &term.X)
+		FeNeg(&term.T, &term.T)
+	}
+	var cached CachedGroupElement
+	term.ToCached(&cached)
+	return cached
+}
+
+// ScalarMultVartime sets out = k*p. Unlike ScalarMult, it runs in time
+// that depends on k, and so must only be used when k is not secret (for
+// example, because it is part of a signature being verified).
+func ScalarMultVartime(out *ExtendedGroupElement, k *[32]byte, p *ExtendedGroupElement) {
+	naf := scalarToNAF(k)
+	table := buildOddMultiplesTable(p)
+
+	top := 0
+	for i, d := range naf {
+		if d != 0 {
+			top = i
+		}
+	}
+
+	out.Zero()
+	for i := top; i >= 0; i-- {
+		var doubled CompletedGroupElement
+		out.Double(&doubled)
+		doubled.ToExtended(out)
+
+		if naf[i] == 0 {
+			continue
+		}
+		cached := tableLookup(&table, naf[i])
+		var sum CompletedGroupElement
+		PointAdd(&sum, out, &cached)
+		sum.ToExtended(out)
+	}
+}
+
+// MultiScalarMultVartime sets out = sum(scalars[i]*points[i]), using
+// Straus's algorithm to share the doublings of the running total across
+// every term. Like ScalarMultVartime, it runs in time that depends on
+// the scalars, and so must only be used when none of them are secret.
+func MultiScalarMultVartime(out *ExtendedGroupElement, scalars []*[32]byte, points []*ExtendedGroupElement) {
+	if len(scalars) != len(points) {
+		panic("edwards25519: MultiScalarMultVartime called with mismatched scalars and points")
+	}
+
+	nafs := make([][nafDigits]int8, len(scalars))
+	tables := make([][oddMultiplesTableSize]ExtendedGroupElement, len(points))
+	top := 0
+	for i := range scalars {
+		nafs[i] = scalarToNAF(scalars[i])
+		tables[i] = buildOddMultiplesTable(points[i])
+		for j, d := range nafs[i] {
+			if d != 0 && j > top {
+				top = j
+			}
+		}
+	}
+
+	out.Zero()
+	for i := top; i >= 0; i-- {
+		var doubled CompletedGroupElement
+		out.Double(&doubled)
+		doubled.ToExtended(out)
+
+		for j := range scalars {
+			d := nafs[j][i]
+			if d == 0 {
+				continue
+			}
+			cached := tableLookup(&tables[j], d)
+			var sum CompletedGroupElement
+			PointAdd(&sum, out, &cached)
+			sum.ToExtended(out)
+		}
+	}
+}
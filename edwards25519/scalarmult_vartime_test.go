@@ -0,0 +1,65 @@
+package edwards25519
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestScalarMultVartimeMatchesScalarMult(t *testing.T) {
+	var rp ExtendedGroupElement
+	randomPoint(&rp)
+
+	for i := 0; i < 20; i++ {
+		var k [32]byte
+		rand.Read(k[:])
+		k[31] &= 0x1f // keep scalars below 2**253
+
+		var want, got ExtendedGroupElement
+		ScalarMult(&want, &k, &rp)
+		ScalarMultVartime(&got, &k, &rp)
+
+		if !want.toBasicPoint().Equals(got.toBasicPoint()) {
+			t.Fatalf("ScalarMultVartime(%x, P) = %s, want %s", k, got.toBasicPoint(), want.toBasicPoint())
+		}
+	}
+}
+
+func TestMultiScalarMultVartimeMatchesDoubleScalarMult(t *testing.T) {
+	var rp1, rp2 ExtendedGroupElement
+	randomPoint(&rp1)
+	randomPoint(&rp2)
+
+	var k1, k2 [32]byte
+	rand.Read(k1[:])
+	rand.Read(k2[:])
+	k1[31] &= 0x1f
+	k2[31] &= 0x1f
+
+	var want ExtendedGroupElement
+	DoubleScalarMult(&want, &k1, &rp1, &k2, &rp2)
+
+	var got ExtendedGroupElement
+	MultiScalarMultVartime(&got, []*[32]byte{&k1, &k2}, []*ExtendedGroupElement{&rp1, &rp2})
+
+	if !want.toBasicPoint().Equals(got.toBasicPoint()) {
+		t.Fatalf("MultiScalarMultVartime = %s, want %s", got.toBasicPoint(), want.toBasicPoint())
+	}
+}
+
+func TestMultiScalarMultVartimeSingleTermMatchesScalarMultVartime(t *testing.T) {
+	var rp ExtendedGroupElement
+	randomPoint(&rp)
+
+	var k [32]byte
+	rand.Read(k[:])
+
+	var want ExtendedGroupElement
+	ScalarMultVartime(&want, &k, &rp)
+
+	var got ExtendedGroupElement
+	MultiScalarMultVartime(&got, []*[32]byte{&k}, []*ExtendedGroupElement{&rp})
+
+	if !want.toBasicPoint().Equals(got.toBasicPoint()) {
+		t.Fatalf("MultiScalarMultVartime([k], [P]) = %s, want %s", got.toBasicPoint(), want.toBasicPoint())
+	}
+}
@@ -0,0 +1,70 @@
+package keyformat
+
+import (
+	"errors"
+	"math/big"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet: the 62 ASCII letters
+// and digits with 0, O, I, and l removed to avoid visual ambiguity.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Radix = big.NewInt(58)
+
+// base58Encode encodes b as a base58 string, preserving leading zero
+// bytes as leading '1' characters the way Bitcoin's encoding does.
+func base58Encode(b []byte) string {
+	zero := 0
+	for zero < len(b) && b[zero] == 0 {
+		zero++
+	}
+
+	n := new(big.Int).SetBytes(b)
+	var out []byte
+	mod := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, base58Radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zero; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+
+	// out was built least-significant digit first; reverse it.
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// base58Decode is the inverse of base58Encode.
+func base58Decode(s string) ([]byte, error) {
+	zero := 0
+	for zero < len(s) && s[zero] == base58Alphabet[0] {
+		zero++
+	}
+
+	n := new(big.Int)
+	for i := zero; i < len(s); i++ {
+		d := indexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, errors.New("keyformat: invalid base58 character")
+		}
+		n.Mul(n, base58Radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	decoded := n.Bytes()
+	out := make([]byte, zero+len(decoded))
+	copy(out[zero:], decoded)
+	return out, nil
+}
+
+func indexByte(alphabet string, c byte) int {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
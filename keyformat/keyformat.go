@@ -0,0 +1,179 @@
+// Package keyformat implements a small, self-describing envelope for
+// public keys: a varint type tag followed by the raw key bytes. This
+// lets applications store or transmit keys from more than one curve
+// (Ed25519 today, with room to add others such as secp256k1 or
+// BLS12-381 G1/G2 later) without an out-of-band way to tell them apart.
+package keyformat
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+// Key type tags. New curves should be given the next unused value;
+// existing tags must never be reassigned, since they may already be
+// persisted.
+const (
+	// KeyTypeEd25519 identifies a 32-byte Ed25519 public key.
+	KeyTypeEd25519 uint64 = 1
+)
+
+// Compress prepends a varint-encoded keyType tag to pubKey, returning
+// the combined envelope. If keyType is KeyTypeEd25519, pubKey is
+// validated as a point on the curve and in the prime-order subgroup
+// before encoding.
+func Compress(pubKey []byte, keyType uint64) ([]byte, error) {
+	if keyType == KeyTypeEd25519 {
+		if err := validateEd25519PublicKey(pubKey); err != nil {
+			return nil, err
+		}
+	}
+
+	var tag [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tag[:], keyType)
+
+	out := make([]byte, 0, n+len(pubKey))
+	out = append(out, tag[:n]...)
+	out = append(out, pubKey...)
+	return out, nil
+}
+
+// Decompress splits an envelope produced by Compress back into its key
+// type and raw key bytes. If the key type is KeyTypeEd25519, pubKey is
+// validated as a point on the curve and in the prime-order subgroup.
+func Decompress(data []byte) (pubKey []byte, keyType uint64, err error) {
+	keyType, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, 0, errors.New("keyformat: invalid or missing type tag")
+	}
+	pubKey = data[n:]
+
+	if keyType == KeyTypeEd25519 {
+		if err := validateEd25519PublicKey(pubKey); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return pubKey, keyType, nil
+}
+
+// validateEd25519PublicKey checks that pubKey decodes to a point on the
+// curve and that the point lies in the prime-order subgroup, by
+// multiplying it by the subgroup order ℓ and checking that the result
+// is the identity element.
+func validateEd25519PublicKey(pubKey []byte) error {
+	if len(pubKey) != 32 {
+		return errors.New("keyformat: ed25519 public key must be 32 bytes")
+	}
+
+	var encoded [32]byte
+	copy(encoded[:], pubKey)
+
+	var A edwards25519.ExtendedGroupElement
+	if !A.FromBytes(&encoded) {
+		return errors.New("keyformat: ed25519 public key is not a valid curve point")
+	}
+
+	var orderTimesA edwards25519.ExtendedGroupElement
+	edwards25519.ScalarMult(&orderTimesA, &groupOrderBytes, &A)
+
+	var out [32]byte
+	orderTimesA.ToBytes(&out)
+	if out != identityBytes {
+		return errors.New("keyformat: ed25519 public key is not in the prime-order subgroup")
+	}
+
+	return nil
+}
+
+// groupOrderBytes is ℓ, the order of the edwards25519 prime-order
+// subgroup, encoded little-endian.
+var groupOrderBytes = [32]byte{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+// identityBytes is the canonical encoding of the curve's identity
+// element (0, 1).
+var identityBytes = [32]byte{1}
+
+// CompressHex is Compress followed by hex encoding.
+func CompressHex(pubKey []byte, keyType uint64) (string, error) {
+	b, err := Compress(pubKey, keyType)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DecompressHex is the inverse of CompressHex.
+func DecompressHex(s string) ([]byte, uint64, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Decompress(b)
+}
+
+// CompressBase64 is Compress followed by unpadded URL-safe base64
+// encoding.
+func CompressBase64(pubKey []byte, keyType uint64) (string, error) {
+	b, err := Compress(pubKey, keyType)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecompressBase64 is the inverse of CompressBase64.
+func DecompressBase64(s string) ([]byte, uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Decompress(b)
+}
+
+// CompressBase32 is Compress followed by unpadded base32 encoding.
+func CompressBase32(pubKey []byte, keyType uint64) (string, error) {
+	b, err := Compress(pubKey, keyType)
+	if err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// DecompressBase32 is the inverse of CompressBase32.
+func DecompressBase32(s string) ([]byte, uint64, error) {
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Decompress(b)
+}
+
+// CompressBase58 is Compress followed by base58 encoding (Bitcoin
+// alphabet).
+func CompressBase58(pubKey []byte, keyType uint64) (string, error) {
+	b, err := Compress(pubKey, keyType)
+	if err != nil {
+		return "", err
+	}
+	return base58Encode(b), nil
+}
+
+// DecompressBase58 is the inverse of CompressBase58.
+func DecompressBase58(s string) ([]byte, uint64, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	return Decompress(b)
+}
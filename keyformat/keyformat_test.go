@@ -0,0 +1,93 @@
+package keyformat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/agl/ed25519"
+)
+
+func TestCompressDecompressRoundTrip(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, err := Compress(pub[:], KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("Compress: %v", err)
+	}
+
+	decompressed, keyType, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress: %v", err)
+	}
+	if keyType != KeyTypeEd25519 {
+		t.Fatalf("keyType = %d, want %d", keyType, KeyTypeEd25519)
+	}
+	if !bytes.Equal(decompressed, pub[:]) {
+		t.Fatalf("Decompress(Compress(pub)) = %x, want %x", decompressed, pub[:])
+	}
+}
+
+func TestCompressRejectsOffCurvePoint(t *testing.T) {
+	// y = 2 has no x with -x^2 + y^2 = 1 + d*x^2*y^2, so this encoding
+	// does not decode to any point.
+	var notAPoint [32]byte
+	notAPoint[0] = 2
+
+	if _, err := Compress(notAPoint[:], KeyTypeEd25519); err == nil {
+		t.Fatal("Compress accepted a public key that is not a valid curve point")
+	}
+}
+
+func TestEncodingWrappersRoundTrip(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hexStr, err := CompressHex(pub[:], KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("CompressHex: %v", err)
+	}
+	if decoded, keyType, err := DecompressHex(hexStr); err != nil || keyType != KeyTypeEd25519 || !bytes.Equal(decoded, pub[:]) {
+		t.Fatalf("DecompressHex round trip failed: %x, %d, %v", decoded, keyType, err)
+	}
+
+	b32, err := CompressBase32(pub[:], KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("CompressBase32: %v", err)
+	}
+	if decoded, keyType, err := DecompressBase32(b32); err != nil || keyType != KeyTypeEd25519 || !bytes.Equal(decoded, pub[:]) {
+		t.Fatalf("DecompressBase32 round trip failed: %x, %d, %v", decoded, keyType, err)
+	}
+
+	b64, err := CompressBase64(pub[:], KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("CompressBase64: %v", err)
+	}
+	if decoded, keyType, err := DecompressBase64(b64); err != nil || keyType != KeyTypeEd25519 || !bytes.Equal(decoded, pub[:]) {
+		t.Fatalf("DecompressBase64 round trip failed: %x, %d, %v", decoded, keyType, err)
+	}
+
+	b58, err := CompressBase58(pub[:], KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("CompressBase58: %v", err)
+	}
+	if decoded, keyType, err := DecompressBase58(b58); err != nil || keyType != KeyTypeEd25519 || !bytes.Equal(decoded, pub[:]) {
+		t.Fatalf("DecompressBase58 round trip failed: %x, %d, %v", decoded, keyType, err)
+	}
+}
+
+func TestBase58EncodePreservesLeadingZeros(t *testing.T) {
+	in := []byte{0, 0, 1, 2, 3}
+	out, err := base58Decode(base58Encode(in))
+	if err != nil {
+		t.Fatalf("base58Decode: %v", err)
+	}
+	if !bytes.Equal(out, in) {
+		t.Fatalf("base58 round trip = %x, want %x", out, in)
+	}
+}
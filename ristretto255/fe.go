@@ -0,0 +1,130 @@
+package ristretto255
+
+import "math/big"
+
+// This file collects the field-element constants and helpers used by the
+// Elligator2-based map in FromUniformBytes. They mirror the big.Int
+// arithmetic in ed25519_big_test.go rather than the fixed-width arithmetic in
+// package edwards25519, since the map is not on any verification or
+// signing hot path.
+
+var (
+	// fieldPrime is 2**255-19, the field over which curve25519 and
+	// edwards25519 are defined.
+	fieldPrime *big.Int
+	// curveD is the edwards25519 curve parameter -121665/121666 mod p.
+	curveD *big.Int
+	// sqrtM1 is a square root of -1 mod p.
+	sqrtM1 *big.Int
+	// fieldPrimeMinus5Over8 is (p-5)/8, the exponent used by the
+	// candidate square root in sqrtRatioM1.
+	fieldPrimeMinus5Over8 *big.Int
+	// oneMinusDSq is 1 - d^2 mod p.
+	oneMinusDSq *big.Int
+	// dMinusOneSq is (d-1)^2 mod p.
+	dMinusOneSq *big.Int
+	// sqrtADMinusOne is a square root of a*d-1 mod p, where a = -1 is the
+	// edwards25519 curve coefficient.
+	sqrtADMinusOne *big.Int
+
+	bigOne = big.NewInt(1)
+)
+
+func init() {
+	fieldPrime, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+	curveD, _ = new(big.Int).SetString("52036cee2b6ffe738cc740797779e89800700a4d4141d8ab75eb4dca135978a3", 16)
+	sqrtM1, _ = new(big.Int).SetString("2b8324804fc1df0b2b4d00993dfbd7a72f431806ad2fe478c4ee1b274a0ea0b0", 16)
+	oneMinusDSq, _ = new(big.Int).SetString("29072a8b2b3e0d79994abddbe70dfe42c81a138cd5e350fe27c09c1945fc176", 16)
+	dMinusOneSq, _ = new(big.Int).SetString("5968b37af66c22414cdcd32f529b4eebd29e4a2cb01e199931ad5aaa44ed4d20", 16)
+	sqrtADMinusOne, _ = new(big.Int).SetString("376931bf2b8348ac0f3cfcc931f5d1fdaf9d8e0c1b7854bd7e97f6a0497b2e1b", 16)
+
+	fieldPrimeMinus5Over8 = new(big.Int).Sub(fieldPrime, big.NewInt(5))
+	fieldPrimeMinus5Over8.Rsh(fieldPrimeMinus5Over8, 3)
+}
+
+// feFromLEBytes interprets buf as a 32-byte little-endian number and
+// reduces it mod p. Per the Ristretto one-way map, bit 255 (the high
+// bit of buf[31]) is ignored rather than folded into the reduction, so
+// that FromUniformBytes agrees with other implementations of
+// hash-to-group.
+func feFromLEBytes(buf *[32]byte) *big.Int {
+	var reversed [32]byte
+	for i := range buf {
+		reversed[i] = buf[31-i]
+	}
+	reversed[0] &= 0x7f
+	v := new(big.Int).SetBytes(reversed[:])
+	return v.Mod(v, fieldPrime)
+}
+
+// feToLEBytes encodes v, which must already be reduced mod p, into buf
+// as a 32-byte little-endian number.
+func feToLEBytes(buf *[32]byte, v *big.Int) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	b := v.Bytes()
+	for i := 0; i < len(b); i++ {
+		buf[i] = b[len(b)-(1+i)]
+	}
+}
+
+// ctAbs returns v if v's canonical representative is even, and -v
+// otherwise (following the IS_NEGATIVE convention from the Ristretto
+// specification, where the sign of a field element is the low bit of
+// its encoding). The name keeps faith with the specification; like
+// the rest of this file it is not actually constant-time, since
+// FromUniformBytes operates on public input.
+func ctAbs(v *big.Int) *big.Int {
+	r := new(big.Int).Mod(v, fieldPrime)
+	if r.Bit(0) == 1 {
+		r.Sub(fieldPrime, r)
+	}
+	return r
+}
+
+// sqrtRatioM1 computes a square root of u/v, if one exists, following
+// the SQRT_RATIO_M1 algorithm from the Ristretto specification. It
+// returns (s, true) such that s^2 == u/v mod p if u/v is square, and
+// otherwise (s, false) with s^2 == i*u/v mod p, where i is sqrtM1.
+func sqrtRatioM1(u, v *big.Int) (*big.Int, bool) {
+	v3 := new(big.Int).Mul(v, v)
+	v3.Mul(v3, v)
+	v3.Mod(v3, fieldPrime)
+
+	v7 := new(big.Int).Mul(v3, v3)
+	v7.Mul(v7, v)
+	v7.Mod(v7, fieldPrime)
+
+	uv7 := new(big.Int).Mul(u, v7)
+	uv7.Mod(uv7, fieldPrime)
+	candidate := new(big.Int).Exp(uv7, fieldPrimeMinus5Over8, fieldPrime)
+
+	r := new(big.Int).Mul(u, v3)
+	r.Mul(r, candidate)
+	r.Mod(r, fieldPrime)
+
+	check := new(big.Int).Mul(v, r)
+	check.Mul(check, r)
+	check.Mod(check, fieldPrime)
+
+	uMod := new(big.Int).Mod(u, fieldPrime)
+	negU := new(big.Int).Neg(uMod)
+	negU.Mod(negU, fieldPrime)
+	negUTimesI := new(big.Int).Mul(negU, sqrtM1)
+	negUTimesI.Mod(negUTimesI, fieldPrime)
+
+	correctSign := check.Cmp(uMod) == 0
+	flippedSign := check.Cmp(negU) == 0
+	flippedSignI := check.Cmp(negUTimesI) == 0
+
+	if flippedSign || flippedSignI {
+		r.Mul(r, sqrtM1)
+		r.Mod(r, fieldPrime)
+	}
+
+	// The specification requires the nonnegative square root.
+	r = ctAbs(r)
+
+	return r, correctSign || flippedSign
+}
@@ -0,0 +1,240 @@
+// Package ristretto255 implements the Ristretto255 prime-order group, a
+// cofactor-free wrapper around the edwards25519 curve used elsewhere in
+// this module. See https://ristretto.group/ for the rationale: building
+// higher-level protocols (Schnorr proofs, VRFs, and so on) directly on
+// top of an Edwards curve with a cofactor of 8 is a well known source of
+// subtle bugs, since distinct byte strings can decode to points that
+// differ only by a small-order component. Every Element produced or
+// accepted by this package instead corresponds to exactly one element of
+// the prime-order quotient group.
+package ristretto255
+
+import (
+	"crypto/subtle"
+	"math/big"
+
+	"github.com/agl/ed25519/edwards25519"
+)
+
+// Element is a member of the Ristretto255 group.
+type Element struct {
+	ge edwards25519.ExtendedGroupElement
+}
+
+// NewElement returns a new Element set to the group identity.
+func NewElement() *Element {
+	e := new(Element)
+	e.ge.Zero()
+	return e
+}
+
+// Add sets e = a + b and returns e.
+func (e *Element) Add(a, b *Element) *Element {
+	var bCached edwards25519.CachedGroupElement
+	var sum edwards25519.CompletedGroupElement
+	b.ge.ToCached(&bCached)
+	edwards25519.PointAdd(&sum, &a.ge, &bCached)
+	sum.ToExtended(&e.ge)
+	return e
+}
+
+// Subtract sets e = a - b and returns e.
+func (e *Element) Subtract(a, b *Element) *Element {
+	var negB Element
+	negB.Negate(b)
+	return e.Add(a, &negB)
+}
+
+// Negate sets e = -a and returns e.
+func (e *Element) Negate(a *Element) *Element {
+	e.ge = a.ge
+	edwards25519.FeNeg(&e.ge.X, &e.ge.X)
+	edwards25519.FeNeg(&e.ge.T, &e.ge.T)
+	return e
+}
+
+// Equal returns 1 if e == a, and 0 otherwise. Unlike comparing decoded
+// curve points directly, this is safe to use on Ristretto255 Elements
+// because each one has a unique canonical encoding.
+func (e *Element) Equal(a *Element) int {
+	var eb, ab [32]byte
+	e.Bytes(&eb)
+	a.Bytes(&ab)
+	return subtle.ConstantTimeCompare(eb[:], ab[:])
+}
+
+// ScalarBaseMult sets e = s*B, where B is the group's base point, and
+// returns e.
+func (e *Element) ScalarBaseMult(s *Scalar) *Element {
+	var k [32]byte
+	s.scalarBytes(&k)
+	edwards25519.GeScalarMultBase(&e.ge, &k)
+	return e
+}
+
+// ScalarMult sets e = s*p and returns e.
+func (e *Element) ScalarMult(s *Scalar, p *Element) *Element {
+	var k [32]byte
+	s.scalarBytes(&k)
+	edwards25519.ScalarMult(&e.ge, &k, &p.ge)
+	return e
+}
+
+// MultiScalarMult sets e = scalars[0]*points[0] + ... + scalars[n-1]*points[n-1]
+// and returns e. It generalizes the double-and-add loop behind
+// edwards25519.DoubleScalarMult to an arbitrary number of terms: each
+// point is doubled once per scalar bit on its own, and each term is
+// added into a single running total under a constant-time conditional
+// move. No doublings are shared between terms.
+func (e *Element) MultiScalarMult(scalars []*Scalar, points []*Element) *Element {
+	if len(scalars) != len(points) {
+		panic("ristretto255: MultiScalarMult called with mismatched scalars and points")
+	}
+
+	ks := make([][32]byte, len(scalars))
+	tmp := make([]edwards25519.ExtendedGroupElement, len(points))
+	for i := range scalars {
+		scalars[i].scalarBytes(&ks[i])
+		tmp[i] = points[i].ge
+	}
+
+	var out, moved edwards25519.ExtendedGroupElement
+	var cached edwards25519.CachedGroupElement
+	var completed edwards25519.CompletedGroupElement
+	out.Zero()
+
+	for bix := 0; bix < 32; bix++ {
+		for bitNum := uint(8); bitNum > 0; bitNum-- {
+			for i := range tmp {
+				tmp[i].ToCached(&cached)
+				edwards25519.PointAdd(&completed, &out, &cached)
+				completed.ToExtended(&moved)
+				edwards25519.ExtendedGroupElementCMove(&out, &moved, int32((ks[i][bix]>>(8-bitNum))&1))
+
+				tmp[i].Double(&completed)
+				completed.ToExtended(&tmp[i])
+			}
+		}
+	}
+
+	e.ge = out
+	return e
+}
+
+// Bytes sets buf to the canonical 32-byte encoding of e.
+func (e *Element) Bytes(buf *[32]byte) {
+	e.ge.ToRistrettoBytes(buf)
+}
+
+// SetBytes sets e to the element represented by the canonical encoding
+// buf and reports whether buf was valid. On failure, e is left
+// unchanged.
+func (e *Element) SetBytes(buf *[32]byte) bool {
+	var ge edwards25519.ExtendedGroupElement
+	if !ge.FromRistrettoBytes(buf) {
+		return false
+	}
+	e.ge = ge
+	return true
+}
+
+// FromUniformBytes sets e to an element derived from buf via the
+// Ristretto one-way map and returns e. Unlike SetBytes, every possible
+// value of buf is accepted, so this is suitable for hashing arbitrary
+// data to a group element (e.g. to implement a random oracle). It maps
+// each 32-byte half of buf onto the curve independently with the
+// Elligator2-based construction from the Ristretto specification, then
+// adds the two resulting points together.
+func (e *Element) FromUniformBytes(buf *[64]byte) *Element {
+	var half1, half2 [32]byte
+	copy(half1[:], buf[:32])
+	copy(half2[:], buf[32:])
+
+	var p1, p2 Element
+	p1.ge = mapToPoint(&half1)
+	p2.ge = mapToPoint(&half2)
+	return e.Add(&p1, &p2)
+}
+
+// mapToPoint implements the Ristretto255 Elligator2 one-way map, taking
+// a 32-byte string interpreted as a field element and returning a point
+// on the curve in extended coordinates.
+func mapToPoint(buf *[32]byte) edwards25519.ExtendedGroupElement {
+	t := feFromLEBytes(buf)
+
+	r := new(big.Int).Mul(sqrtM1, t)
+	r.Mul(r, t)
+	r.Mod(r, fieldPrime)
+
+	u := new(big.Int).Add(r, bigOne)
+	u.Mul(u, oneMinusDSq)
+	u.Mod(u, fieldPrime)
+
+	v := new(big.Int).Mul(r, curveD)
+	v.Add(v, bigOne)
+	v.Neg(v)
+	tmp := new(big.Int).Add(curveD, r)
+	v.Mul(v, tmp)
+	v.Mod(v, fieldPrime)
+
+	s, wasSquare := sqrtRatioM1(u, v)
+
+	st := new(big.Int).Mul(s, t)
+	st.Mod(st, fieldPrime)
+	sPrime := ctAbs(st)
+	sPrime.Neg(sPrime)
+	sPrime.Mod(sPrime, fieldPrime)
+
+	c := new(big.Int).Set(r)
+	if wasSquare {
+		c.Sub(fieldPrime, bigOne) // c = -1
+	} else {
+		s = sPrime
+	}
+
+	n := new(big.Int).Sub(r, bigOne)
+	n.Mul(n, c)
+	n.Mul(n, dMinusOneSq)
+	n.Sub(n, v)
+	n.Mod(n, fieldPrime)
+
+	sSq := new(big.Int).Mul(s, s)
+	sSq.Mod(sSq, fieldPrime)
+
+	w0 := new(big.Int).Add(s, s)
+	w0.Mul(w0, v)
+	w0.Mod(w0, fieldPrime)
+
+	w1 := new(big.Int).Mul(n, sqrtADMinusOne)
+	w1.Mod(w1, fieldPrime)
+
+	w2 := new(big.Int).Sub(bigOne, sSq)
+	w2.Mod(w2, fieldPrime)
+
+	w3 := new(big.Int).Add(bigOne, sSq)
+	w3.Mod(w3, fieldPrime)
+
+	// The map naturally produces the point (W0*W3 : W2*W1 : W1*W3 : W0*W2)
+	// in extended coordinates; divide out the common W1*W3 factor to get
+	// affine (x, y) = (W0/W1, W2/W3), which edwards25519.FromBytes can
+	// then turn back into extended coordinates via the usual compressed
+	// point decoding.
+	var w1Inv, w3Inv big.Int
+	w1Inv.ModInverse(w1, fieldPrime)
+	w3Inv.ModInverse(w3, fieldPrime)
+
+	x := new(big.Int).Mul(w0, &w1Inv)
+	x.Mod(x, fieldPrime)
+	y := new(big.Int).Mul(w2, &w3Inv)
+	y.Mod(y, fieldPrime)
+
+	var encoded [32]byte
+	feToLEBytes(&encoded, y)
+	if x.Bit(0) == 1 {
+		encoded[31] |= 0x80
+	}
+
+	var ge edwards25519.ExtendedGroupElement
+	ge.FromBytes(&encoded)
+	return ge
+}
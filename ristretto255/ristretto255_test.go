@@ -0,0 +1,248 @@
+package ristretto255
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+func randomScalar(t *testing.T) *Scalar {
+	var buf [64]byte
+	if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	return NewScalar().SetUniformBytes(&buf)
+}
+
+func TestScalarBaseMultAndEqual(t *testing.T) {
+	s := randomScalar(t)
+
+	a := new(Element).ScalarBaseMult(s)
+	b := new(Element).ScalarBaseMult(s)
+	if a.Equal(b) != 1 {
+		t.Fatal("ScalarBaseMult is not deterministic")
+	}
+
+	s2 := randomScalar(t)
+	c := new(Element).ScalarBaseMult(s2)
+	if a.Equal(c) == 1 {
+		t.Fatal("distinct scalars produced equal elements")
+	}
+}
+
+func TestAddSubtractNegate(t *testing.T) {
+	s1, s2 := randomScalar(t), randomScalar(t)
+	p1 := new(Element).ScalarBaseMult(s1)
+	p2 := new(Element).ScalarBaseMult(s2)
+
+	sum := new(Element).Add(p1, p2)
+	back := new(Element).Subtract(sum, p2)
+	if back.Equal(p1) != 1 {
+		t.Fatalf("Subtract(Add(p1, p2), p2) != p1")
+	}
+
+	var negSum Element
+	negSum.Negate(sum)
+	identity := new(Element).Add(sum, &negSum)
+	if identity.Equal(NewElement()) != 1 {
+		t.Fatalf("p + (-p) != identity")
+	}
+}
+
+func TestMultiScalarMultMatchesSequentialSum(t *testing.T) {
+	const n = 5
+	scalars := make([]*Scalar, n)
+	points := make([]*Element, n)
+	want := NewElement()
+	for i := 0; i < n; i++ {
+		scalars[i] = randomScalar(t)
+		points[i] = new(Element).ScalarBaseMult(randomScalar(t))
+		term := new(Element).ScalarMult(scalars[i], points[i])
+		want.Add(want, term)
+	}
+
+	got := new(Element).MultiScalarMult(scalars, points)
+	if got.Equal(want) != 1 {
+		t.Fatalf("MultiScalarMult did not match sequential scalar mults and adds")
+	}
+}
+
+func TestElementBytesRoundTrip(t *testing.T) {
+	s := randomScalar(t)
+	p := new(Element).ScalarBaseMult(s)
+
+	var buf [32]byte
+	p.Bytes(&buf)
+
+	p2 := NewElement()
+	if !p2.SetBytes(&buf) {
+		t.Fatalf("SetBytes rejected a canonical encoding")
+	}
+	if p.Equal(p2) != 1 {
+		t.Fatalf("SetBytes(Bytes(p)) != p")
+	}
+}
+
+func TestFromUniformBytesDeterministic(t *testing.T) {
+	var buf [64]byte
+	if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	a := new(Element).FromUniformBytes(&buf)
+	b := new(Element).FromUniformBytes(&buf)
+	if a.Equal(b) != 1 {
+		t.Fatalf("FromUniformBytes is not deterministic")
+	}
+
+	var encoded [32]byte
+	a.Bytes(&encoded)
+	if !NewElement().SetBytes(&encoded) {
+		t.Fatalf("FromUniformBytes produced a non-canonical element encoding")
+	}
+}
+
+func TestFromUniformBytesIgnoresTopBit(t *testing.T) {
+	// The Ristretto one-way map reduces each 32-byte half of the input
+	// mod p after discarding bit 255, so flipping that bit in either
+	// half must not change the resulting element.
+	var buf [64]byte
+	if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
+		t.Fatal(err)
+	}
+	buf[31] &= 0x7f
+	buf[63] &= 0x7f
+
+	want := new(Element).FromUniformBytes(&buf)
+
+	var flippedFirstHalf = buf
+	flippedFirstHalf[31] |= 0x80
+	if got := new(Element).FromUniformBytes(&flippedFirstHalf); got.Equal(want) != 1 {
+		t.Fatalf("flipping bit 255 of the first half changed the mapped element")
+	}
+
+	var flippedSecondHalf = buf
+	flippedSecondHalf[63] |= 0x80
+	if got := new(Element).FromUniformBytes(&flippedSecondHalf); got.Equal(want) != 1 {
+		t.Fatalf("flipping bit 255 of the second half changed the mapped element")
+	}
+}
+
+// TestFromUniformBytesKnownAnswers checks FromUniformBytes against the
+// published test vectors for the Ristretto255 one-way map, from
+// draft-irtf-cfrg-ristretto255-decaf448-04 appendix A.3. These are the
+// only vectors in this file with an answer fixed by a source outside
+// this package, so they are what would catch a sign error or wrong
+// constant in mapToPoint that happens to still satisfy every
+// self-consistency check above.
+func TestFromUniformBytesKnownAnswers(t *testing.T) {
+	for i, tc := range []struct {
+		input string
+		want  string
+	}{
+		{
+			"5d1be09e3d0c82fc538112490e35701979d99e06ca3e2b5b54bffe8b4dc772c" +
+				"14d98b696a1bbfb5ca32c436cc61c16563790306c79eaca7705668b47dffe5bb6",
+			"3066f82a1a747d45120d1740f14358531a8f04bbffe6a819f86dfe50f44a0a46",
+		},
+		{
+			"f116b34b8f17ceb56e8732a60d913dd10cce47a6d53bee9204be8b44f6678b2" +
+				"70102a56902e2488c46120e9276cfe54638286b9e4b3cdb470b542d46c2068d38",
+			"f26e5b6f7d362d2d2a94c5d0e7602cb4773c95a2e5c31a64f133189fa76ed61b",
+		},
+		{
+			"8422e1bbdaab52938b81fd602effb6f89110e1e57208ad12d9ad767e2e25510" +
+				"c27140775f9337088b982d83d7fcf0b2fa1edffe51952cbe7365e95c86eaf325c",
+			"006ccd2a9e6867e6a2c5cea83d3302cc9de128dd2a9a57dd8ee7b9d7ffe02826",
+		},
+		{
+			"ac22415129b61427bf464e17baee8db65940c233b98afce8d17c57beeb7876c" +
+				"2150d15af1cb1fb824bbd14955f2b57d08d388aab431a391cfc33d5bafb5dbbaf",
+			"f8f0c87cf237953c5890aec3998169005dae3eca1fbb04548c635953c817f92a",
+		},
+		{
+			"165d697a1ef3d5cf3c38565beefcf88c0f282b8e7dbd28544c483432f1cec76" +
+				"75debea8ebb4e5fe7d6f6e5db15f15587ac4d4d4a1de7191e0c1ca6664abcc413",
+			"ae81e7dedf20a497e10c304a765c1767a42d6e06029758d2d7e8ef7cc4c41179",
+		},
+		{
+			"a836e6c9a9ca9f1e8d486273ad56a78c70cf18f0ce10abb1c7172ddd605d7fd" +
+				"2979854f47ae1ccf204a33102095b4200e5befc0465accc263175485f0e17ea5c",
+			"e2705652ff9f5e44d3e841bf1c251cf7dddb77d140870d1ab2ed64f1a9ce8628",
+		},
+		{
+			"2cdc11eaeb95daf01189417cdddbf95952993aa9cb9c640eb5058d09702c746" +
+				"22c9965a697a3b345ec24ee56335b556e677b30e6f90ac77d781064f866a3c982",
+			"80bd07262511cdde4863f8a7434cef696750681cb9510eea557088f76d9e5065",
+		},
+		// The remaining four vectors all have bit 255 set in one or both
+		// halves, and all four map to the same point: this is what
+		// exercises the masking in feFromLEBytes.
+		{
+			"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff" +
+				"1200000000000000000000000000000000000000000000000000000000000000",
+			"304282791023b73128d277bdcb5c7746ef2eac08dde9f2983379cb8e5ef0517f",
+		},
+		{
+			"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f" +
+				"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"304282791023b73128d277bdcb5c7746ef2eac08dde9f2983379cb8e5ef0517f",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000080" +
+				"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+			"304282791023b73128d277bdcb5c7746ef2eac08dde9f2983379cb8e5ef0517f",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000" +
+				"1200000000000000000000000000000000000000000000000000000000000080",
+			"304282791023b73128d277bdcb5c7746ef2eac08dde9f2983379cb8e5ef0517f",
+		},
+	} {
+		var buf [64]byte
+		b, err := hex.DecodeString(tc.input)
+		if err != nil || len(b) != 64 {
+			t.Fatalf("case %d: bad test vector input: %v", i, err)
+		}
+		copy(buf[:], b)
+
+		e := new(Element).FromUniformBytes(&buf)
+		var got [32]byte
+		e.Bytes(&got)
+
+		want, err := hex.DecodeString(tc.want)
+		if err != nil {
+			t.Fatalf("case %d: bad test vector output: %v", i, err)
+		}
+		if !bytes.Equal(got[:], want) {
+			t.Fatalf("case %d: FromUniformBytes(%s) = %x, want %x", i, tc.input, got, want)
+		}
+	}
+}
+
+func TestScalarSetCanonicalBytesRejectsOutOfRange(t *testing.T) {
+	// order itself, little-endian, is not a canonical scalar encoding.
+	var buf [32]byte
+	b := order.Bytes()
+	for i := 0; i < len(b); i++ {
+		buf[i] = b[len(b)-(1+i)]
+	}
+
+	if _, err := NewScalar().SetCanonicalBytes(&buf); err == nil {
+		t.Fatalf("SetCanonicalBytes accepted ℓ as a canonical scalar")
+	}
+}
+
+func TestScalarInvert(t *testing.T) {
+	s := randomScalar(t)
+	inv := NewScalar().Invert(s)
+	product := NewScalar().Multiply(s, inv)
+
+	var buf [32]byte
+	product.Bytes(&buf)
+	if buf != ([32]byte{1}) {
+		t.Fatalf("s * (1/s) != 1")
+	}
+}
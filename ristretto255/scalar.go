@@ -0,0 +1,117 @@
+package ristretto255
+
+import (
+	"errors"
+	"math/big"
+)
+
+// order is the order ℓ of the Ristretto255 group (and of the
+// edwards25519 prime-order subgroup it wraps): 2**252 +
+// 27742317777372353535851937790883648493.
+var order, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// Scalar is an element of the scalar field of the Ristretto255 group,
+// i.e. an integer modulo ℓ.
+type Scalar struct {
+	v *big.Int
+}
+
+// NewScalar returns a new Scalar set to 0.
+func NewScalar() *Scalar {
+	return &Scalar{v: new(big.Int)}
+}
+
+func (s *Scalar) val() *big.Int {
+	if s.v == nil {
+		s.v = new(big.Int)
+	}
+	return s.v
+}
+
+// Add sets s = a + b mod ℓ and returns s.
+func (s *Scalar) Add(a, b *Scalar) *Scalar {
+	s.val().Add(a.val(), b.val())
+	s.v.Mod(s.v, order)
+	return s
+}
+
+// Subtract sets s = a - b mod ℓ and returns s.
+func (s *Scalar) Subtract(a, b *Scalar) *Scalar {
+	s.val().Sub(a.val(), b.val())
+	s.v.Mod(s.v, order)
+	return s
+}
+
+// Multiply sets s = a*b mod ℓ and returns s.
+func (s *Scalar) Multiply(a, b *Scalar) *Scalar {
+	s.val().Mul(a.val(), b.val())
+	s.v.Mod(s.v, order)
+	return s
+}
+
+// Negate sets s = -a mod ℓ and returns s.
+func (s *Scalar) Negate(a *Scalar) *Scalar {
+	s.val().Neg(a.val())
+	s.v.Mod(s.v, order)
+	return s
+}
+
+// Invert sets s = 1/a mod ℓ and returns s. The zero scalar has no
+// inverse; Invert leaves s set to 0 in that case.
+func (s *Scalar) Invert(a *Scalar) *Scalar {
+	s.val()
+	if a.val().Sign() == 0 {
+		s.v.SetInt64(0)
+		return s
+	}
+	s.v.ModInverse(a.v, order)
+	return s
+}
+
+// SetUniformBytes sets s to the value of buf, interpreted as a 64-byte
+// little-endian number and reduced mod ℓ, and returns s. This is the
+// standard way to turn a wide (e.g. SHA-512) hash output into a scalar
+// without introducing bias.
+func (s *Scalar) SetUniformBytes(buf *[64]byte) *Scalar {
+	var reversed [64]byte
+	for i := range buf {
+		reversed[i] = buf[63-i]
+	}
+	s.val().SetBytes(reversed[:])
+	s.v.Mod(s.v, order)
+	return s
+}
+
+// SetCanonicalBytes sets s to the value of buf, interpreted as a 32-byte
+// little-endian number, and returns s. It returns an error, leaving s
+// unchanged, if buf is not the canonical encoding of a scalar, i.e. if
+// the encoded value is not already reduced mod ℓ.
+func (s *Scalar) SetCanonicalBytes(buf *[32]byte) (*Scalar, error) {
+	var reversed [32]byte
+	for i := range buf {
+		reversed[i] = buf[31-i]
+	}
+	v := new(big.Int).SetBytes(reversed[:])
+	if v.Cmp(order) >= 0 {
+		return nil, errors.New("ristretto255: invalid scalar encoding")
+	}
+	s.val().Set(v)
+	return s, nil
+}
+
+// Bytes sets buf to the canonical 32-byte little-endian encoding of s.
+func (s *Scalar) Bytes(buf *[32]byte) {
+	s.scalarBytes(buf)
+}
+
+// scalarBytes encodes s as a little-endian [32]byte, the form expected
+// by the edwards25519 package's scalar multiplication routines.
+func (s *Scalar) scalarBytes(buf *[32]byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+	b := s.val().Bytes()
+	for i := 0; i < len(b); i++ {
+		buf[i] = b[len(b)-(1+i)]
+	}
+}
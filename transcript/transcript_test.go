@@ -0,0 +1,64 @@
+package transcript
+
+import "testing"
+
+func TestChallengeScalarDeterministic(t *testing.T) {
+	mk := func() [32]byte {
+		tr := NewTranscript("test protocol")
+		tr.AppendMessage("msg", []byte("hello"))
+		var out [32]byte
+		tr.ChallengeScalar("challenge", &out)
+		return out
+	}
+
+	if mk() != mk() {
+		t.Fatal("ChallengeScalar is not deterministic for identical transcripts")
+	}
+}
+
+func TestChallengeScalarDependsOnLabel(t *testing.T) {
+	tr1 := NewTranscript("protocol A")
+	tr1.AppendMessage("msg", []byte("hello"))
+	var out1 [32]byte
+	tr1.ChallengeScalar("challenge", &out1)
+
+	tr2 := NewTranscript("protocol B")
+	tr2.AppendMessage("msg", []byte("hello"))
+	var out2 [32]byte
+	tr2.ChallengeScalar("challenge", &out2)
+
+	if out1 == out2 {
+		t.Fatal("transcripts with different protocol labels produced the same challenge")
+	}
+}
+
+func TestChallengeScalarConsumesState(t *testing.T) {
+	tr := NewTranscript("test protocol")
+	tr.AppendMessage("msg", []byte("hello"))
+
+	var out1, out2 [32]byte
+	tr.ChallengeScalar("challenge", &out1)
+	tr.ChallengeScalar("challenge", &out2)
+
+	if out1 == out2 {
+		t.Fatal("two successive ChallengeScalar calls produced the same output")
+	}
+}
+
+func TestChallengeScalarDistinguishesMessageBoundaries(t *testing.T) {
+	tr1 := NewTranscript("test protocol")
+	tr1.AppendMessage("a", []byte("foo"))
+	tr1.AppendMessage("b", []byte("bar"))
+	var out1 [32]byte
+	tr1.ChallengeScalar("challenge", &out1)
+
+	tr2 := NewTranscript("test protocol")
+	tr2.AppendMessage("a", []byte("foob"))
+	tr2.AppendMessage("b", []byte("ar"))
+	var out2 [32]byte
+	tr2.ChallengeScalar("challenge", &out2)
+
+	if out1 == out2 {
+		t.Fatal("length-prefixing failed to distinguish differently split messages")
+	}
+}